@@ -0,0 +1,116 @@
+package aihelper
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+)
+
+// Cache lets AIHelper skip repeat model calls for deterministic prompts
+// (title generation, tag extraction, summarization, and similar helper
+// calls) by storing the raw text response keyed on everything that affects
+// it. Implementations must be safe for concurrent use. AIHelper uses a
+// bounded in-memory LRU cache by default; see WithCache, RedisCache, and
+// BoltCache for alternatives.
+type Cache interface {
+	// Get returns the cached response for key. ok is false on a miss, which
+	// is not an error.
+	Get(ctx context.Context, key string) (value string, ok bool, err error)
+	// Set stores value under key, evicting or expiring older entries per
+	// the implementation's own policy.
+	Set(ctx context.Context, key, value string) error
+}
+
+// CacheMetrics is a point-in-time snapshot of an AIHelper's cache
+// performance, as returned by AIHelper.CacheMetrics.
+type CacheMetrics struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// Option configures an AIHelper at construction time, unlike GenerateOption
+// which applies to a single call.
+type Option func(*AIHelper)
+
+// WithCache overrides the default in-memory LRU cache with the given Cache
+// implementation, e.g. RedisCache or BoltCache, so cached responses can be
+// shared across processes or survive restarts.
+func WithCache(cache Cache) Option {
+	return func(a *AIHelper) {
+		a.cache = cache
+	}
+}
+
+// NoCache skips the cache for this call, guaranteeing a fresh model
+// response even if an identical call was cached earlier.
+func NoCache() GenerateOption {
+	return func(o *generateOptions) {
+		o.skipCache = true
+	}
+}
+
+// CacheMetrics returns a snapshot of the AIHelper's cache hit/miss counts
+// since construction.
+func (a *AIHelper) CacheMetrics() CacheMetrics {
+	return CacheMetrics{
+		Hits:   atomic.LoadUint64(&a.cacheHits),
+		Misses: atomic.LoadUint64(&a.cacheMisses),
+	}
+}
+
+func (a *AIHelper) recordCacheHit() {
+	atomic.AddUint64(&a.cacheHits, 1)
+}
+
+func (a *AIHelper) recordCacheMiss() {
+	atomic.AddUint64(&a.cacheMisses, 1)
+}
+
+// cacheKey derives a stable key for a call from everything that affects its
+// output: the model, instructions, input, any sampling overrides, and the
+// shape of the requested output. schema is nil for a *string output (raw
+// text) and the JSON Schema generated from the target type otherwise; two
+// calls that are otherwise identical but want different output shapes (e.g.
+// one wants a raw string, another wants that same text parsed into a
+// struct) must not collide, or whichever ran first poisons the cache for
+// the other.
+func cacheKey(o generateOptions, instructions, input string, schema map[string]any) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "model=%s\ninstructions=%s\ninput=%s\n", o.modelName, instructions, input)
+
+	if schema != nil {
+		schemaJSON, err := json.Marshal(schema)
+		if err != nil {
+			schemaJSON = []byte("{}")
+		}
+		fmt.Fprintf(h, "schema=%s\n", schemaJSON)
+	} else {
+		fmt.Fprint(h, "shape=string\n")
+	}
+
+	if o.temperature != nil {
+		fmt.Fprintf(h, "temperature=%v\n", *o.temperature)
+	}
+	if o.topP != nil {
+		fmt.Fprintf(h, "top_p=%v\n", *o.topP)
+	}
+	if o.maxTokens != nil {
+		fmt.Fprintf(h, "max_tokens=%v\n", *o.maxTokens)
+	}
+	if o.seed != nil {
+		fmt.Fprintf(h, "seed=%v\n", *o.seed)
+	}
+	if o.presencePenalty != nil {
+		fmt.Fprintf(h, "presence_penalty=%v\n", *o.presencePenalty)
+	}
+	if o.frequencyPenalty != nil {
+		fmt.Fprintf(h, "frequency_penalty=%v\n", *o.frequencyPenalty)
+	}
+	for _, stop := range o.stop {
+		fmt.Fprintf(h, "stop=%s\n", stop)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
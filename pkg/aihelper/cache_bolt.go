@@ -0,0 +1,52 @@
+package aihelper
+
+import (
+	"context"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// BoltCache is a Cache backed by an embedded BoltDB file, useful for
+// single-process deployments that want cached responses to survive
+// restarts without standing up Redis.
+type BoltCache struct {
+	db     *bolt.DB
+	bucket []byte
+}
+
+// NewBoltCache returns a BoltCache that stores entries in bucket, creating
+// it if it doesn't already exist.
+func NewBoltCache(db *bolt.DB, bucket string) (*BoltCache, error) {
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(bucket))
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return &BoltCache{db: db, bucket: []byte(bucket)}, nil
+}
+
+func (c *BoltCache) Get(ctx context.Context, key string) (value string, ok bool, err error) {
+	if err := ctx.Err(); err != nil {
+		return "", false, err
+	}
+
+	err = c.db.View(func(tx *bolt.Tx) error {
+		if v := tx.Bucket(c.bucket).Get([]byte(key)); v != nil {
+			value, ok = string(v), true
+		}
+		return nil
+	})
+	return value, ok, err
+}
+
+func (c *BoltCache) Set(ctx context.Context, key, value string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return c.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(c.bucket).Put([]byte(key), []byte(value))
+	})
+}
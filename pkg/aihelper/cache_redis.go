@@ -0,0 +1,43 @@
+package aihelper
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisCache is a Cache backed by a Redis instance, suitable for sharing
+// cached responses across multiple AIHelper processes.
+type RedisCache struct {
+	client    *redis.Client
+	keyPrefix string
+	ttl       time.Duration
+}
+
+// NewRedisCache returns a RedisCache that stores entries under
+// keyPrefix+key and expires them after ttl. A ttl of zero means entries
+// never expire.
+func NewRedisCache(client *redis.Client, keyPrefix string, ttl time.Duration) *RedisCache {
+	return &RedisCache{
+		client:    client,
+		keyPrefix: keyPrefix,
+		ttl:       ttl,
+	}
+}
+
+func (c *RedisCache) Get(ctx context.Context, key string) (string, bool, error) {
+	value, err := c.client.Get(ctx, c.keyPrefix+key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+func (c *RedisCache) Set(ctx context.Context, key, value string) error {
+	return c.client.Set(ctx, c.keyPrefix+key, value, c.ttl).Err()
+}
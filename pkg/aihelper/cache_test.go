@@ -0,0 +1,47 @@
+package aihelper
+
+import (
+	"reflect"
+	"testing"
+)
+
+type cacheTestPerson struct {
+	Name string `json:"name"`
+}
+
+type cacheTestAddress struct {
+	City string `json:"city"`
+}
+
+func TestCacheKeyDiffersByOutputShape(t *testing.T) {
+	o := newGenerateOptions(&AIHelper{modelName: "gpt-4o"})
+
+	stringKey := cacheKey(o, "summarize", "some input", nil)
+	objectKey := cacheKey(o, "summarize", "some input", generateSchema(reflect.TypeOf(cacheTestPerson{}), false))
+
+	if stringKey == objectKey {
+		t.Fatal("expected *string output and struct output to produce different cache keys")
+	}
+}
+
+func TestCacheKeyStableForIdenticalCalls(t *testing.T) {
+	o := newGenerateOptions(&AIHelper{modelName: "gpt-4o"})
+
+	a := cacheKey(o, "summarize", "some input", nil)
+	b := cacheKey(o, "summarize", "some input", nil)
+
+	if a != b {
+		t.Fatalf("expected identical calls to produce the same cache key, got %q and %q", a, b)
+	}
+}
+
+func TestCacheKeyDiffersBySchema(t *testing.T) {
+	o := newGenerateOptions(&AIHelper{modelName: "gpt-4o"})
+
+	personKey := cacheKey(o, "extract", "some input", generateSchema(reflect.TypeOf(cacheTestPerson{}), false))
+	addressKey := cacheKey(o, "extract", "some input", generateSchema(reflect.TypeOf(cacheTestAddress{}), false))
+
+	if personKey == addressKey {
+		t.Fatal("expected different target types to produce different cache keys")
+	}
+}
@@ -0,0 +1,67 @@
+package aihelper
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// defaultCacheSize is the capacity of the in-memory cache AIHelper uses when
+// none is configured via WithCache.
+const defaultCacheSize = 256
+
+// lruCache is a simple size-bounded, in-memory Cache. It evicts the least
+// recently used entry once it grows past capacity.
+type lruCache struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List
+}
+
+type lruEntry struct {
+	key   string
+	value string
+}
+
+func newLRUCache(capacity int) *lruCache {
+	return &lruCache{
+		capacity: capacity,
+		items:    make(map[string]*list.Element, capacity),
+		order:    list.New(),
+	}
+}
+
+func (c *lruCache) Get(_ context.Context, key string) (string, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return "", false, nil
+	}
+
+	c.order.MoveToFront(el)
+	return el.Value.(*lruEntry).value, true, nil
+}
+
+func (c *lruCache) Set(_ context.Context, key, value string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		el.Value.(*lruEntry).value = value
+		c.order.MoveToFront(el)
+		return nil
+	}
+
+	c.items[key] = c.order.PushFront(&lruEntry{key: key, value: value})
+
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry).key)
+	}
+
+	return nil
+}
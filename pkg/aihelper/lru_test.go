@@ -0,0 +1,62 @@
+package aihelper
+
+import (
+	"context"
+	"testing"
+)
+
+func TestLRUCacheGetSet(t *testing.T) {
+	c := newLRUCache(2)
+	ctx := context.Background()
+
+	if _, ok, err := c.Get(ctx, "a"); err != nil || ok {
+		t.Fatalf("expected miss on empty cache, got ok=%v err=%v", ok, err)
+	}
+
+	if err := c.Set(ctx, "a", "1"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	value, ok, err := c.Get(ctx, "a")
+	if err != nil || !ok || value != "1" {
+		t.Fatalf("expected hit value=1, got value=%q ok=%v err=%v", value, ok, err)
+	}
+}
+
+func TestLRUCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newLRUCache(2)
+	ctx := context.Background()
+
+	_ = c.Set(ctx, "a", "1")
+	_ = c.Set(ctx, "b", "2")
+
+	// Touch "a" so "b" becomes the least recently used entry.
+	if _, ok, _ := c.Get(ctx, "a"); !ok {
+		t.Fatal("expected \"a\" to still be cached")
+	}
+
+	_ = c.Set(ctx, "c", "3")
+
+	if _, ok, _ := c.Get(ctx, "b"); ok {
+		t.Fatal("expected \"b\" to have been evicted as least recently used")
+	}
+	if _, ok, _ := c.Get(ctx, "a"); !ok {
+		t.Fatal("expected \"a\" to remain cached after being touched")
+	}
+	if _, ok, _ := c.Get(ctx, "c"); !ok {
+		t.Fatal("expected \"c\" to be cached as the most recent entry")
+	}
+}
+
+func TestLRUCacheSetOverwritesExisting(t *testing.T) {
+	c := newLRUCache(2)
+	ctx := context.Background()
+
+	_ = c.Set(ctx, "a", "1")
+	_ = c.Set(ctx, "a", "2")
+
+	value, ok, err := c.Get(ctx, "a")
+	if err != nil || !ok || value != "2" {
+		t.Fatalf("expected updated value=2, got value=%q ok=%v err=%v", value, ok, err)
+	}
+}
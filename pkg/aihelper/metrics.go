@@ -0,0 +1,21 @@
+package aihelper
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	tokensTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "aihelper",
+		Name:      "tokens_total",
+		Help:      "Total tokens used by AIHelper calls, by usage label, model, and token kind (prompt/completion).",
+	}, []string{"label", "model", "kind"})
+
+	estimatedCostUSDTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "aihelper",
+		Name:      "estimated_cost_usd_total",
+		Help:      "Estimated USD cost of AIHelper calls, by usage label and model.",
+	}, []string{"label", "model"})
+)
+
+func init() {
+	prometheus.MustRegister(tokensTotal, estimatedCostUSDTotal)
+}
@@ -0,0 +1,173 @@
+package aihelper
+
+import (
+	"time"
+
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/obot-platform/nah/pkg/typed"
+)
+
+const (
+	defaultMaxRetries   = 2
+	defaultRetryBackoff = 250 * time.Millisecond
+)
+
+// generateOptions holds the per-call settings that can be overridden via
+// GenerateOption. The zero value is never used directly; see
+// newGenerateOptions.
+type generateOptions struct {
+	modelName        string
+	temperature      *float32
+	topP             *float32
+	maxTokens        *int
+	seed             *int
+	presencePenalty  *float32
+	frequencyPenalty *float32
+	stop             []string
+
+	maxRetries   int
+	retryBackoff time.Duration
+	strictSchema bool
+	skipCache    bool
+	usageLabel   string
+}
+
+// GenerateOption overrides a single sampling parameter for one
+// GenerateObject, GenerateObjectStream, or GenerateStringStream call,
+// without affecting the AIHelper's defaults. The exception is
+// WithMaxRetries and WithRetryBackoff: GenerateObjectStream and
+// GenerateStringStream don't retry a malformed response the way
+// GenerateObject does, so those two options are silently no-ops when
+// passed to either streaming method.
+type GenerateOption func(*generateOptions)
+
+// WithModel overrides the model used for this call in place of the
+// AIHelper's default modelName.
+func WithModel(modelName string) GenerateOption {
+	return func(o *generateOptions) {
+		o.modelName = modelName
+	}
+}
+
+// WithTemperature overrides the default temperature of 0.7 for this call.
+func WithTemperature(temperature float32) GenerateOption {
+	return func(o *generateOptions) {
+		o.temperature = typed.Pointer(temperature)
+	}
+}
+
+// WithTopP sets the nucleus sampling parameter for this call.
+func WithTopP(topP float32) GenerateOption {
+	return func(o *generateOptions) {
+		o.topP = typed.Pointer(topP)
+	}
+}
+
+// WithMaxTokens caps the number of tokens generated for this call.
+func WithMaxTokens(maxTokens int) GenerateOption {
+	return func(o *generateOptions) {
+		o.maxTokens = typed.Pointer(maxTokens)
+	}
+}
+
+// WithSeed requests (best-effort, provider-dependent) deterministic sampling
+// for this call.
+func WithSeed(seed int) GenerateOption {
+	return func(o *generateOptions) {
+		o.seed = typed.Pointer(seed)
+	}
+}
+
+// WithPresencePenalty sets the presence penalty for this call.
+func WithPresencePenalty(penalty float32) GenerateOption {
+	return func(o *generateOptions) {
+		o.presencePenalty = typed.Pointer(penalty)
+	}
+}
+
+// WithFrequencyPenalty sets the frequency penalty for this call.
+func WithFrequencyPenalty(penalty float32) GenerateOption {
+	return func(o *generateOptions) {
+		o.frequencyPenalty = typed.Pointer(penalty)
+	}
+}
+
+// WithStop sets the stop sequences for this call.
+func WithStop(stop ...string) GenerateOption {
+	return func(o *generateOptions) {
+		o.stop = stop
+	}
+}
+
+// WithMaxRetries sets how many times GenerateObject will ask the model to
+// repair malformed JSON output before giving up, in addition to the initial
+// attempt. The default is 2 retries.
+func WithMaxRetries(maxRetries int) GenerateOption {
+	return func(o *generateOptions) {
+		o.maxRetries = maxRetries
+	}
+}
+
+// WithRetryBackoff sets the base delay before the first repair retry; the
+// delay doubles after each subsequent retry. The default is 250ms.
+func WithRetryBackoff(backoff time.Duration) GenerateOption {
+	return func(o *generateOptions) {
+		o.retryBackoff = backoff
+	}
+}
+
+// StrictSchema rejects output containing fields not declared on the target
+// Go type, instead of silently ignoring them as encoding/json does by
+// default. A violation is treated the same as malformed JSON: it triggers
+// the same repair-retry path as an unmarshal failure.
+func StrictSchema() GenerateOption {
+	return func(o *generateOptions) {
+		o.strictSchema = true
+	}
+}
+
+// WithUsageLabel tags this call's token usage with label (e.g. a subject,
+// user, or feature name) so it can be retrieved separately via
+// AIHelper.Usage. Calls with no label are aggregated under "". Usage is
+// also exported as Prometheus metrics keyed by label, so label should come
+// from a small, bounded set (a feature name, not a per-request user ID) to
+// avoid unbounded metric cardinality.
+func WithUsageLabel(label string) GenerateOption {
+	return func(o *generateOptions) {
+		o.usageLabel = label
+	}
+}
+
+// toolDef builds the gptscript.ToolDef for a call, given the instructions,
+// whether the response must be JSON, and the JSON schema to constrain it to
+// (nil if none applies).
+func (o generateOptions) toolDef(instructions string, jsonResponse bool, schema map[string]any) gptscript.ToolDef {
+	return gptscript.ToolDef{
+		ModelName:          o.modelName,
+		JSONResponse:       jsonResponse,
+		Instructions:       instructions,
+		Temperature:        o.temperature,
+		TopP:               o.topP,
+		MaxTokens:          o.maxTokens,
+		Seed:               o.seed,
+		PresencePenalty:    o.presencePenalty,
+		FrequencyPenalty:   o.frequencyPenalty,
+		Stop:               o.stop,
+		JSONResponseSchema: schema,
+	}
+}
+
+// newGenerateOptions builds the default options for a call on the given
+// AIHelper, then applies opts in order.
+func newGenerateOptions(a *AIHelper, opts ...GenerateOption) generateOptions {
+	o := generateOptions{
+		modelName:    a.modelName,
+		temperature:  typed.Pointer((float32)(0.7)),
+		maxRetries:   defaultMaxRetries,
+		retryBackoff: defaultRetryBackoff,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
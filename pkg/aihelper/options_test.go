@@ -0,0 +1,164 @@
+package aihelper
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGenerateOptionsMutateExpectedField(t *testing.T) {
+	o := newGenerateOptions(&AIHelper{modelName: "gpt-4o"})
+
+	WithModel("gpt-4o-mini")(&o)
+	if o.modelName != "gpt-4o-mini" {
+		t.Fatalf("WithModel: modelName = %q, want %q", o.modelName, "gpt-4o-mini")
+	}
+
+	WithTemperature(0.2)(&o)
+	if o.temperature == nil || *o.temperature != 0.2 {
+		t.Fatalf("WithTemperature: temperature = %v, want 0.2", o.temperature)
+	}
+
+	WithTopP(0.9)(&o)
+	if o.topP == nil || *o.topP != 0.9 {
+		t.Fatalf("WithTopP: topP = %v, want 0.9", o.topP)
+	}
+
+	WithMaxTokens(256)(&o)
+	if o.maxTokens == nil || *o.maxTokens != 256 {
+		t.Fatalf("WithMaxTokens: maxTokens = %v, want 256", o.maxTokens)
+	}
+
+	WithSeed(42)(&o)
+	if o.seed == nil || *o.seed != 42 {
+		t.Fatalf("WithSeed: seed = %v, want 42", o.seed)
+	}
+
+	WithPresencePenalty(0.5)(&o)
+	if o.presencePenalty == nil || *o.presencePenalty != 0.5 {
+		t.Fatalf("WithPresencePenalty: presencePenalty = %v, want 0.5", o.presencePenalty)
+	}
+
+	WithFrequencyPenalty(0.3)(&o)
+	if o.frequencyPenalty == nil || *o.frequencyPenalty != 0.3 {
+		t.Fatalf("WithFrequencyPenalty: frequencyPenalty = %v, want 0.3", o.frequencyPenalty)
+	}
+
+	WithStop("\n", "END")(&o)
+	if !reflect.DeepEqual(o.stop, []string{"\n", "END"}) {
+		t.Fatalf("WithStop: stop = %v, want [\\n END]", o.stop)
+	}
+
+	WithMaxRetries(5)(&o)
+	if o.maxRetries != 5 {
+		t.Fatalf("WithMaxRetries: maxRetries = %d, want 5", o.maxRetries)
+	}
+
+	WithRetryBackoff(time.Second)(&o)
+	if o.retryBackoff != time.Second {
+		t.Fatalf("WithRetryBackoff: retryBackoff = %v, want %v", o.retryBackoff, time.Second)
+	}
+
+	StrictSchema()(&o)
+	if !o.strictSchema {
+		t.Fatal("StrictSchema: strictSchema = false, want true")
+	}
+
+	WithUsageLabel("summarization")(&o)
+	if o.usageLabel != "summarization" {
+		t.Fatalf("WithUsageLabel: usageLabel = %q, want %q", o.usageLabel, "summarization")
+	}
+}
+
+func TestNewGenerateOptionsDefaults(t *testing.T) {
+	o := newGenerateOptions(&AIHelper{modelName: "gpt-4o"})
+
+	if o.modelName != "gpt-4o" {
+		t.Fatalf("modelName = %q, want %q", o.modelName, "gpt-4o")
+	}
+	if o.temperature == nil || *o.temperature != 0.7 {
+		t.Fatalf("default temperature = %v, want 0.7", o.temperature)
+	}
+	if o.maxRetries != defaultMaxRetries {
+		t.Fatalf("default maxRetries = %d, want %d", o.maxRetries, defaultMaxRetries)
+	}
+	if o.retryBackoff != defaultRetryBackoff {
+		t.Fatalf("default retryBackoff = %v, want %v", o.retryBackoff, defaultRetryBackoff)
+	}
+}
+
+func TestNewGenerateOptionsAppliesOverridesInOrder(t *testing.T) {
+	o := newGenerateOptions(&AIHelper{modelName: "gpt-4o"}, WithModel("gpt-4o-mini"), WithTemperature(0.1))
+
+	if o.modelName != "gpt-4o-mini" {
+		t.Fatalf("modelName = %q, want %q", o.modelName, "gpt-4o-mini")
+	}
+	if o.temperature == nil || *o.temperature != 0.1 {
+		t.Fatalf("temperature = %v, want 0.1", o.temperature)
+	}
+}
+
+func TestWithModelOverridesModelNameNotJustToolDef(t *testing.T) {
+	a := &AIHelper{modelName: "gpt-4o"}
+	o := newGenerateOptions(a, WithModel("gpt-4o-mini"))
+
+	// o.modelName drives rate limiting, usage accounting, and cache-key
+	// derivation, not just the ToolDef sent to gptscript; confirm the
+	// override reaches it rather than only the ToolDef fields.
+	if o.modelName != "gpt-4o-mini" {
+		t.Fatalf("o.modelName = %q, want override %q, not AIHelper's default %q", o.modelName, "gpt-4o-mini", a.modelName)
+	}
+
+	td := o.toolDef("do the thing", false, nil)
+	if td.ModelName != "gpt-4o-mini" {
+		t.Fatalf("toolDef().ModelName = %q, want %q", td.ModelName, "gpt-4o-mini")
+	}
+}
+
+func TestGenerateOptionsToolDefWiresFields(t *testing.T) {
+	o := newGenerateOptions(&AIHelper{modelName: "gpt-4o"},
+		WithTopP(0.8),
+		WithMaxTokens(128),
+		WithSeed(7),
+		WithPresencePenalty(0.1),
+		WithFrequencyPenalty(0.2),
+		WithStop("STOP"),
+	)
+
+	schema := map[string]any{"type": "object"}
+	td := o.toolDef("summarize this", true, schema)
+
+	if td.ModelName != "gpt-4o" {
+		t.Fatalf("toolDef().ModelName = %q, want %q", td.ModelName, "gpt-4o")
+	}
+	if !td.JSONResponse {
+		t.Fatal("toolDef().JSONResponse = false, want true")
+	}
+	if td.Instructions != "summarize this" {
+		t.Fatalf("toolDef().Instructions = %q, want %q", td.Instructions, "summarize this")
+	}
+	if td.Temperature == nil || *td.Temperature != 0.7 {
+		t.Fatalf("toolDef().Temperature = %v, want 0.7", td.Temperature)
+	}
+	if td.TopP == nil || *td.TopP != 0.8 {
+		t.Fatalf("toolDef().TopP = %v, want 0.8", td.TopP)
+	}
+	if td.MaxTokens == nil || *td.MaxTokens != 128 {
+		t.Fatalf("toolDef().MaxTokens = %v, want 128", td.MaxTokens)
+	}
+	if td.Seed == nil || *td.Seed != 7 {
+		t.Fatalf("toolDef().Seed = %v, want 7", td.Seed)
+	}
+	if td.PresencePenalty == nil || *td.PresencePenalty != 0.1 {
+		t.Fatalf("toolDef().PresencePenalty = %v, want 0.1", td.PresencePenalty)
+	}
+	if td.FrequencyPenalty == nil || *td.FrequencyPenalty != 0.2 {
+		t.Fatalf("toolDef().FrequencyPenalty = %v, want 0.2", td.FrequencyPenalty)
+	}
+	if !reflect.DeepEqual(td.Stop, []string{"STOP"}) {
+		t.Fatalf("toolDef().Stop = %v, want [STOP]", td.Stop)
+	}
+	if !reflect.DeepEqual(td.JSONResponseSchema, schema) {
+		t.Fatalf("toolDef().JSONResponseSchema = %v, want %v", td.JSONResponseSchema, schema)
+	}
+}
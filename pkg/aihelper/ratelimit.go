@@ -0,0 +1,103 @@
+package aihelper
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	defaultRateLimitRetries = 5
+	defaultRateLimitBackoff = time.Second
+)
+
+// modelLimiter caps requests and tokens per minute for one model.
+type modelLimiter struct {
+	requests *rate.Limiter
+	tokens   *rate.Limiter
+}
+
+// WithRateLimit caps calls against model to at most rpm requests and tpm
+// tokens per minute, so a burst of GenerateObject calls backs off instead of
+// overwhelming the provider. A limit of 0 means that dimension is
+// unlimited.
+func WithRateLimit(model string, rpm, tpm int) Option {
+	return func(a *AIHelper) {
+		if a.limiters == nil {
+			a.limiters = map[string]*modelLimiter{}
+		}
+		a.limiters[model] = &modelLimiter{
+			requests: newLimiter(rpm, time.Minute),
+			tokens:   newLimiter(tpm, time.Minute),
+		}
+	}
+}
+
+func newLimiter(limit int, per time.Duration) *rate.Limiter {
+	if limit <= 0 {
+		return rate.NewLimiter(rate.Inf, 0)
+	}
+	return rate.NewLimiter(rate.Limit(float64(limit)/per.Seconds()), limit)
+}
+
+// wait blocks until model has budget, per any limiter configured via
+// WithRateLimit, for one request and estimatedTokens tokens. It is a no-op
+// if model has no limiter configured. A single call that estimates more
+// tokens than the configured tpm allows is clamped to the limiter's burst
+// size rather than rejected outright, since the provider may still be able
+// to serve it.
+func (a *AIHelper) wait(ctx context.Context, model string, estimatedTokens int) error {
+	limiter, ok := a.limiters[model]
+	if !ok {
+		return nil
+	}
+
+	if err := limiter.requests.Wait(ctx); err != nil {
+		return err
+	}
+
+	if burst := limiter.tokens.Burst(); burst > 0 && estimatedTokens > burst {
+		estimatedTokens = burst
+	}
+	return limiter.tokens.WaitN(ctx, estimatedTokens)
+}
+
+// isRateLimitErr reports whether err looks like a 429/rate-limit response
+// from the underlying model provider.
+func isRateLimitErr(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "429") ||
+		strings.Contains(msg, "rate limit") ||
+		strings.Contains(msg, "too many requests")
+}
+
+// retryOnRateLimit calls fn, retrying with exponential backoff if it
+// returns a rate limit error, up to defaultRateLimitRetries times. Any
+// other error, or exhausting the retries, returns immediately.
+func retryOnRateLimit[T any](ctx context.Context, fn func() (T, error)) (T, error) {
+	backoff := defaultRateLimitBackoff
+	for attempt := 0; ; attempt++ {
+		value, err := fn()
+		if err == nil {
+			return value, nil
+		}
+
+		if !isRateLimitErr(err) || attempt == defaultRateLimitRetries {
+			return value, err
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		}
+		backoff *= 2
+	}
+}
@@ -0,0 +1,108 @@
+package aihelper
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestIsRateLimitErr(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"unrelated error", errors.New("connection reset"), false},
+		{"429 status", errors.New("http 429 from provider"), true},
+		{"rate limit phrase", errors.New("Rate Limit exceeded"), true},
+		{"too many requests phrase", errors.New("too many requests, slow down"), true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := isRateLimitErr(c.err); got != c.want {
+				t.Fatalf("isRateLimitErr(%v) = %v, want %v", c.err, got, c.want)
+			}
+		})
+	}
+}
+
+func TestRetryOnRateLimitReturnsImmediatelyOnSuccess(t *testing.T) {
+	calls := 0
+	value, err := retryOnRateLimit(context.Background(), func() (int, error) {
+		calls++
+		return 42, nil
+	})
+	if err != nil || value != 42 {
+		t.Fatalf("expected (42, nil), got (%d, %v)", value, err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected exactly one call, got %d", calls)
+	}
+}
+
+func TestRetryOnRateLimitReturnsNonRateLimitErrorImmediately(t *testing.T) {
+	calls := 0
+	wantErr := errors.New("boom")
+	_, err := retryOnRateLimit(context.Background(), func() (int, error) {
+		calls++
+		return 0, wantErr
+	})
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("expected %v, got %v", wantErr, err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected a non-rate-limit error to skip retries, got %d calls", calls)
+	}
+}
+
+func TestRetryOnRateLimitStopsOnCtxCancellationDuringBackoff(t *testing.T) {
+	calls := 0
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := retryOnRateLimit(ctx, func() (int, error) {
+		calls++
+		return 0, errors.New("429 too many requests")
+	})
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected the backoff wait to be interrupted after the first attempt, got %d calls", calls)
+	}
+	if elapsed := time.Since(start); elapsed >= defaultRateLimitBackoff {
+		t.Fatalf("expected ctx cancellation to cut the backoff short, took %s", elapsed)
+	}
+}
+
+func TestWaitNoopWithoutConfiguredLimiter(t *testing.T) {
+	a := &AIHelper{}
+	if err := a.wait(context.Background(), "unconfigured-model", 1_000_000); err != nil {
+		t.Fatalf("expected no-op for a model with no rate limiter, got %v", err)
+	}
+}
+
+func TestWaitClampsOversizedRequestToBurst(t *testing.T) {
+	a := &AIHelper{
+		limiters: map[string]*modelLimiter{
+			"gpt-4o": {
+				requests: newLimiter(0, time.Minute),
+				tokens:   newLimiter(100, time.Minute),
+			},
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// Without clamping, WaitN for more tokens than the limiter's burst
+	// (100) would reject the request outright instead of waiting.
+	if err := a.wait(ctx, "gpt-4o", 10_000); err != nil {
+		t.Fatalf("expected an oversized request to be clamped to the burst size instead of rejected, got %v", err)
+	}
+}
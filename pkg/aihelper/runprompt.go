@@ -3,46 +3,193 @@ package aihelper
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
 
 	"github.com/gptscript-ai/go-gptscript"
-	"github.com/obot-platform/nah/pkg/typed"
 )
 
 type AIHelper struct {
-	gptscript *gptscript.GPTScript
+	gptscript evaluator
 	modelName string
+
+	cache                  Cache
+	cacheHits, cacheMisses uint64
+
+	usage    *usageTracker
+	limiters map[string]*modelLimiter
 }
 
-func New(gptscript *gptscript.GPTScript, modelName string) *AIHelper {
-	return &AIHelper{
-		gptscript: gptscript,
+// New constructs an AIHelper that uses gptscript to run prompts against
+// modelName by default. It caches responses in a bounded in-memory LRU
+// cache unless WithCache is passed to use a different Cache implementation.
+func New(gptscript *gptscript.GPTScript, modelName string, opts ...Option) *AIHelper {
+	a := &AIHelper{
+		gptscript: gptscriptEvaluator{client: gptscript},
 		modelName: modelName,
+		cache:     newLRUCache(defaultCacheSize),
+		usage:     newUsageTracker(),
+	}
+
+	for _, opt := range opts {
+		opt(a)
 	}
+
+	return a
 }
 
-func (a *AIHelper) GenerateObject(ctx context.Context, output any, instructions, input string) error {
-	outputStr, isString := output.(*string)
-	run, err := a.gptscript.Evaluate(ctx, gptscript.Options{
-		Input: input,
-	}, gptscript.ToolDef{
-		ModelName:    a.modelName,
-		JSONResponse: !isString,
-		Instructions: instructions,
-		Temperature:  typed.Pointer((float32)(0.7)),
+// evaluator is the subset of *gptscript.GPTScript that AIHelper depends on,
+// narrowed to an interface so the retry/backoff loop in GenerateObject (and
+// the streaming run setup in stream.go) can be exercised with a fake
+// evaluator in tests instead of a live gptscript call.
+type evaluator interface {
+	Evaluate(ctx context.Context, opts gptscript.Options, toolDef gptscript.ToolDef) (streamRun, error)
+}
+
+// gptscriptEvaluator adapts *gptscript.GPTScript to the evaluator interface.
+type gptscriptEvaluator struct {
+	client *gptscript.GPTScript
+}
+
+func (g gptscriptEvaluator) Evaluate(ctx context.Context, opts gptscript.Options, toolDef gptscript.ToolDef) (streamRun, error) {
+	return g.client.Evaluate(ctx, opts, toolDef)
+}
+
+// evaluate runs toolDef against input, honoring any rate limit configured
+// for o.modelName via WithRateLimit and retrying with exponential backoff if
+// the provider reports a rate limit error. On success it records token
+// usage under o.usageLabel before returning the model's raw text response.
+func (a *AIHelper) evaluate(ctx context.Context, o generateOptions, toolDef gptscript.ToolDef, input string) (string, error) {
+	promptTokens := countTokens(o.modelName, toolDef.Instructions+input)
+
+	text, err := retryOnRateLimit(ctx, func() (string, error) {
+		if err := a.wait(ctx, o.modelName, promptTokens); err != nil {
+			return "", err
+		}
+		return a.evaluateOnce(ctx, toolDef, input)
 	})
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	text, err := run.Text()
+	a.usage.record(o.usageLabel, o.modelName, promptTokens, countTokens(o.modelName, text))
+	return text, nil
+}
+
+func (a *AIHelper) evaluateOnce(ctx context.Context, toolDef gptscript.ToolDef, input string) (string, error) {
+	run, err := a.gptscript.Evaluate(ctx, gptscript.Options{
+		Input: input,
+	}, toolDef)
 	if err != nil {
-		return err
+		return "", err
+	}
+
+	return run.Text()
+}
+
+// GenerateObject runs instructions against input and unmarshals the result
+// into output. If output is a *string, the raw model text is assigned
+// directly instead of being parsed as JSON. By default this uses the
+// AIHelper's configured model and a temperature of 0.7; pass GenerateOption
+// values to override the model or sampling parameters for this call only.
+//
+// For non-string output, a JSON Schema is derived from output's type and
+// passed to the model as a response-format constraint. If the model's
+// response still fails to unmarshal, GenerateObject feeds the bad output,
+// the schema, and the parse error back to the model and retries, up to
+// WithMaxRetries times (2 by default) with exponential backoff between
+// attempts (WithRetryBackoff, 250ms by default).
+func (a *AIHelper) GenerateObject(ctx context.Context, output any, instructions, input string, opts ...GenerateOption) error {
+	o := newGenerateOptions(a, opts...)
+
+	outputStr, isString := output.(*string)
+
+	var schema map[string]any
+	if !isString {
+		schema = generateSchema(reflect.TypeOf(output), o.strictSchema)
+	}
+
+	var key string
+	if a.cache != nil && !o.skipCache {
+		key = cacheKey(o, instructions, input, schema)
+		if cached, ok, err := a.cache.Get(ctx, key); err == nil && ok {
+			a.recordCacheHit()
+			if isString {
+				*outputStr = cached
+				return nil
+			}
+			return json.Unmarshal([]byte(cached), output)
+		}
+		a.recordCacheMiss()
 	}
 
 	if isString {
+		text, err := a.evaluate(ctx, o, o.toolDef(instructions, false, nil), input)
+		if err != nil {
+			return err
+		}
+
 		*outputStr = text
+		if key != "" {
+			_ = a.cache.Set(ctx, key, text)
+		}
 		return nil
 	}
 
-	return json.Unmarshal([]byte(text), output)
+	attemptInstructions := instructions
+	backoff := o.retryBackoff
+	var lastErr error
+
+	for attempt := 0; attempt <= o.maxRetries; attempt++ {
+		text, err := a.evaluate(ctx, o, o.toolDef(attemptInstructions, true, schema), input)
+		if err != nil {
+			return err
+		}
+
+		err = json.Unmarshal([]byte(text), output)
+		if err == nil && o.strictSchema {
+			err = checkExtraFields(reflect.TypeOf(output), json.RawMessage(text))
+		}
+
+		if err != nil {
+			lastErr = err
+		} else {
+			if key != "" {
+				_ = a.cache.Set(ctx, key, text)
+			}
+			return nil
+		}
+
+		if attempt == o.maxRetries {
+			break
+		}
+
+		attemptInstructions = repairInstructions(instructions, schema, text, lastErr)
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		backoff *= 2
+	}
+
+	return fmt.Errorf("generate object: model did not produce output matching the schema after %d attempts: %w", o.maxRetries+1, lastErr)
+}
+
+// repairInstructions builds the instructions for a retry attempt after the
+// model's output failed to unmarshal: it asks the model to emit only valid
+// JSON matching schema, given the original instructions, the malformed
+// output, and the parse error.
+func repairInstructions(instructions string, schema map[string]any, badOutput string, parseErr error) string {
+	schemaJSON, err := json.Marshal(schema)
+	if err != nil {
+		schemaJSON = []byte("{}")
+	}
+
+	return fmt.Sprintf(
+		"%s\n\nYour previous response could not be parsed as JSON matching the required schema.\n\nSchema:\n%s\n\nYour previous response:\n%s\n\nParse error: %s\n\nRespond again with only valid JSON matching the schema. Do not include any other text.",
+		instructions, schemaJSON, badOutput, parseErr,
+	)
 }
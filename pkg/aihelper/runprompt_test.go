@@ -0,0 +1,140 @@
+package aihelper
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gptscript-ai/go-gptscript"
+)
+
+// fakeEvaluator is a fake evaluator whose responses are returned in order on
+// successive calls (the last one repeats once exhausted), so tests can drive
+// GenerateObject's retry/backoff loop without a live gptscript call.
+type fakeEvaluator struct {
+	calls     int
+	callTimes []time.Time
+	responses []string
+	err       error
+}
+
+func (f *fakeEvaluator) Evaluate(_ context.Context, _ gptscript.Options, _ gptscript.ToolDef) (streamRun, error) {
+	f.calls++
+	f.callTimes = append(f.callTimes, time.Now())
+
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	idx := f.calls - 1
+	if idx >= len(f.responses) {
+		idx = len(f.responses) - 1
+	}
+	return &fakeStreamRun{text: f.responses[idx]}, nil
+}
+
+func TestRepairInstructions(t *testing.T) {
+	schema := map[string]any{"type": "object"}
+	got := repairInstructions("original instructions", schema, "bad output", errors.New("unexpected EOF"))
+
+	for _, want := range []string{"original instructions", "bad output", "unexpected EOF", `{"type":"object"}`} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected repair instructions to contain %q, got %q", want, got)
+		}
+	}
+}
+
+func TestGenerateObjectRetriesMalformedJSONThenSucceeds(t *testing.T) {
+	fe := &fakeEvaluator{responses: []string{"not json", `{"name":"alice"}`}}
+	a := &AIHelper{gptscript: fe, usage: newUsageTracker()}
+
+	var out schemaTestPerson
+	err := a.GenerateObject(context.Background(), &out, "extract the name", "some input", WithRetryBackoff(time.Millisecond))
+	if err != nil {
+		t.Fatalf("expected GenerateObject to recover after a repair retry, got %v", err)
+	}
+	if out.Name != "alice" {
+		t.Fatalf("expected output to be populated from the successful retry, got %+v", out)
+	}
+	if fe.calls != 2 {
+		t.Fatalf("expected exactly 2 calls (1 initial + 1 retry), got %d", fe.calls)
+	}
+}
+
+func TestGenerateObjectExhaustsRetriesAndWrapsLastError(t *testing.T) {
+	fe := &fakeEvaluator{responses: []string{"not json", "still not json", "nope"}}
+	a := &AIHelper{gptscript: fe, usage: newUsageTracker()}
+
+	var out schemaTestPerson
+	err := a.GenerateObject(context.Background(), &out, "extract the name", "some input",
+		WithMaxRetries(2), WithRetryBackoff(time.Millisecond))
+
+	if err == nil {
+		t.Fatal("expected an error once retries are exhausted")
+	}
+	if !strings.Contains(err.Error(), "after 3 attempts") {
+		t.Fatalf("expected the error to report maxRetries+1 attempts, got %v", err)
+	}
+	if fe.calls != 3 {
+		t.Fatalf("expected exactly maxRetries+1 = 3 calls, got %d", fe.calls)
+	}
+}
+
+func TestGenerateObjectBackoffDoublesBetweenRetries(t *testing.T) {
+	fe := &fakeEvaluator{responses: []string{"bad", "bad", "bad"}}
+	a := &AIHelper{gptscript: fe, usage: newUsageTracker()}
+
+	base := 5 * time.Millisecond
+	var out schemaTestPerson
+	_ = a.GenerateObject(context.Background(), &out, "extract", "input", WithMaxRetries(2), WithRetryBackoff(base))
+
+	if len(fe.callTimes) != 3 {
+		t.Fatalf("expected 3 attempts, got %d", len(fe.callTimes))
+	}
+
+	firstGap := fe.callTimes[1].Sub(fe.callTimes[0])
+	secondGap := fe.callTimes[2].Sub(fe.callTimes[1])
+
+	if firstGap < base {
+		t.Fatalf("expected the first retry to wait at least the base backoff %s, waited %s", base, firstGap)
+	}
+	if secondGap < 2*base {
+		t.Fatalf("expected the second retry to wait at least double the base backoff (%s), waited %s", 2*base, secondGap)
+	}
+}
+
+func TestGenerateObjectCtxCancellationDuringBackoff(t *testing.T) {
+	fe := &fakeEvaluator{responses: []string{"bad", "bad"}}
+	a := &AIHelper{gptscript: fe, usage: newUsageTracker()}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+
+	var out schemaTestPerson
+	err := a.GenerateObject(ctx, &out, "extract", "input", WithRetryBackoff(time.Hour))
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if fe.calls != 1 {
+		t.Fatalf("expected the backoff wait to be interrupted after the first attempt, got %d calls", fe.calls)
+	}
+}
+
+func TestGenerateObjectStringOutputSkipsRetryLoop(t *testing.T) {
+	fe := &fakeEvaluator{responses: []string{"hello there"}}
+	a := &AIHelper{gptscript: fe, usage: newUsageTracker()}
+
+	var out string
+	if err := a.GenerateObject(context.Background(), &out, "greet", "some input"); err != nil {
+		t.Fatalf("expected no error for *string output, got %v", err)
+	}
+	if out != "hello there" {
+		t.Fatalf("expected raw text passthrough, got %q", out)
+	}
+	if fe.calls != 1 {
+		t.Fatalf("expected exactly 1 call for *string output (no repair retries), got %d", fe.calls)
+	}
+}
@@ -0,0 +1,200 @@
+package aihelper
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// generateSchema builds a JSON Schema describing t's JSON representation via
+// reflection, for use as a response-format constraint when asking the model
+// for structured output. It covers the subset of Go types GenerateObject
+// callers realistically target: structs, slices, maps, and primitives. If
+// strict is true, every struct object in the schema sets
+// "additionalProperties": false, rejecting fields the target type doesn't
+// declare, and every map object sets "additionalProperties" to the schema
+// of the map's value type.
+func generateSchema(t reflect.Type, strict bool) map[string]any {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		properties := map[string]any{}
+		var required []string
+
+		for _, field := range flattenFields(t) {
+			properties[field.name] = generateSchema(field.typ, strict)
+			if !field.omitempty {
+				required = append(required, field.name)
+			}
+		}
+
+		schema := map[string]any{
+			"type":       "object",
+			"properties": properties,
+		}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		if strict {
+			schema["additionalProperties"] = false
+		}
+		return schema
+
+	case reflect.Slice, reflect.Array:
+		return map[string]any{
+			"type":  "array",
+			"items": generateSchema(t.Elem(), strict),
+		}
+
+	case reflect.Map:
+		schema := map[string]any{"type": "object"}
+		if strict {
+			schema["additionalProperties"] = generateSchema(t.Elem(), strict)
+		}
+		return schema
+
+	case reflect.String:
+		return map[string]any{"type": "string"}
+
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+
+	default:
+		return map[string]any{}
+	}
+}
+
+// checkExtraFields reports an error if raw (a JSON value matching t) or any
+// of its nested objects contain a field not declared on t. encoding/json
+// silently ignores unknown fields by default, so this is what gives
+// StrictSchema teeth when the model doesn't itself honor the
+// "additionalProperties": false hint in the schema it was sent.
+func checkExtraFields(t reflect.Type, raw json.RawMessage) error {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &obj); err != nil {
+			// raw isn't a JSON object; the earlier json.Unmarshal into the
+			// caller's struct would already have failed in that case.
+			return nil
+		}
+
+		fieldTypes := make(map[string]reflect.Type, t.NumField())
+		for _, field := range flattenFields(t) {
+			fieldTypes[field.name] = field.typ
+		}
+
+		for name, value := range obj {
+			fieldType, ok := fieldTypes[name]
+			if !ok {
+				return fmt.Errorf("unexpected field %q", name)
+			}
+			if err := checkExtraFields(fieldType, value); err != nil {
+				return fmt.Errorf("%s.%w", name, err)
+			}
+		}
+		return nil
+
+	case reflect.Slice, reflect.Array:
+		var items []json.RawMessage
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return nil
+		}
+		for i, item := range items {
+			if err := checkExtraFields(t.Elem(), item); err != nil {
+				return fmt.Errorf("[%d]%w", i, err)
+			}
+		}
+		return nil
+
+	case reflect.Map:
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &obj); err != nil {
+			return nil
+		}
+		for key, value := range obj {
+			if err := checkExtraFields(t.Elem(), value); err != nil {
+				return fmt.Errorf("[%q]%w", key, err)
+			}
+		}
+		return nil
+
+	default:
+		return nil
+	}
+}
+
+// jsonField is one field of a struct as encoding/json would marshal it:
+// its resolved name, its Go type, and whether it's omitted when empty.
+type jsonField struct {
+	name      string
+	typ       reflect.Type
+	omitempty bool
+}
+
+// flattenFields walks t's fields the way encoding/json does, honoring
+// `json:"name"`, `json:"-"`, and `,omitempty`, and promoting anonymous
+// (embedded) struct fields into the parent object instead of nesting them
+// under the embedded type's name - unless the embedded field carries an
+// explicit json tag, in which case it's treated as an ordinary named
+// field.
+func flattenFields(t reflect.Type) []jsonField {
+	var fields []jsonField
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			// unexported
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		parts := strings.Split(tag, ",")
+
+		if field.Anonymous && parts[0] == "" {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				fields = append(fields, flattenFields(embedded)...)
+				continue
+			}
+		}
+
+		name := field.Name
+		if parts[0] != "" {
+			name = parts[0]
+		}
+
+		var omitempty bool
+		for _, opt := range parts[1:] {
+			if opt == "omitempty" {
+				omitempty = true
+			}
+		}
+
+		fields = append(fields, jsonField{name: name, typ: field.Type, omitempty: omitempty})
+	}
+
+	return fields
+}
@@ -0,0 +1,188 @@
+package aihelper
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type schemaTestAddress struct {
+	City string `json:"city"`
+	Zip  string `json:"zip,omitempty"`
+}
+
+type schemaTestPerson struct {
+	Name      string              `json:"name"`
+	Addresses []schemaTestAddress `json:"addresses,omitempty"`
+	secret    string
+}
+
+type schemaTestBase struct {
+	ID string `json:"id"`
+}
+
+type schemaTestAnimal struct {
+	schemaTestBase
+	Name string `json:"name"`
+}
+
+type schemaTestTaggedAnimal struct {
+	schemaTestBase `json:"base"`
+	Name           string `json:"name"`
+}
+
+type schemaTestDirectory struct {
+	Entries map[string]schemaTestAddress `json:"entries"`
+}
+
+func TestGenerateSchema(t *testing.T) {
+	schema := generateSchema(reflect.TypeOf(schemaTestPerson{}), false)
+
+	if schema["type"] != "object" {
+		t.Fatalf("expected type object, got %v", schema["type"])
+	}
+
+	props, ok := schema["properties"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected properties map, got %T", schema["properties"])
+	}
+	if _, ok := props["name"]; !ok {
+		t.Fatal("expected properties to include \"name\"")
+	}
+	if _, ok := props["secret"]; ok {
+		t.Fatal("unexported field \"secret\" should not appear in schema")
+	}
+
+	required, _ := schema["required"].([]string)
+	if len(required) != 1 || required[0] != "name" {
+		t.Fatalf("expected only \"name\" to be required, got %v", required)
+	}
+
+	if _, ok := schema["additionalProperties"]; ok {
+		t.Fatal("additionalProperties should be absent when strict=false")
+	}
+}
+
+func TestGenerateSchemaStrict(t *testing.T) {
+	schema := generateSchema(reflect.TypeOf(schemaTestPerson{}), true)
+	if v, ok := schema["additionalProperties"]; !ok || v != false {
+		t.Fatalf("expected additionalProperties=false, got %v", v)
+	}
+}
+
+func TestGenerateSchemaPromotesEmbeddedFields(t *testing.T) {
+	schema := generateSchema(reflect.TypeOf(schemaTestAnimal{}), false)
+
+	props, ok := schema["properties"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected properties map, got %T", schema["properties"])
+	}
+	if _, ok := props["id"]; !ok {
+		t.Fatal("expected embedded field \"id\" to be promoted to the top level")
+	}
+	if _, ok := props["schemaTestBase"]; ok {
+		t.Fatal("embedded field should not appear nested under its type name")
+	}
+}
+
+func TestGenerateSchemaMap(t *testing.T) {
+	schema := generateSchema(reflect.TypeOf(schemaTestDirectory{}), false)
+
+	props, ok := schema["properties"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected properties map, got %T", schema["properties"])
+	}
+	entries, ok := props["entries"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected \"entries\" to be an object schema, got %T", props["entries"])
+	}
+	if entries["type"] != "object" {
+		t.Fatalf("expected map field type object, got %v", entries["type"])
+	}
+	if _, ok := entries["additionalProperties"]; ok {
+		t.Fatal("additionalProperties should be absent when strict=false")
+	}
+}
+
+func TestGenerateSchemaMapStrict(t *testing.T) {
+	schema := generateSchema(reflect.TypeOf(schemaTestDirectory{}), true)
+
+	props := schema["properties"].(map[string]any)
+	entries := props["entries"].(map[string]any)
+
+	valueSchema, ok := entries["additionalProperties"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected additionalProperties to describe the map's value schema, got %v", entries["additionalProperties"])
+	}
+	if valueSchema["type"] != "object" {
+		t.Fatalf("expected map value schema type object, got %v", valueSchema["type"])
+	}
+}
+
+func TestGenerateSchemaKeepsTaggedEmbedNested(t *testing.T) {
+	schema := generateSchema(reflect.TypeOf(schemaTestTaggedAnimal{}), false)
+
+	props, ok := schema["properties"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected properties map, got %T", schema["properties"])
+	}
+	if _, ok := props["base"]; !ok {
+		t.Fatal("expected explicitly tagged embedded field to appear nested under its tag name")
+	}
+	if _, ok := props["id"]; ok {
+		t.Fatal("explicitly tagged embedded field should not be promoted")
+	}
+}
+
+func TestCheckExtraFieldsMatchesPromotedEmbedding(t *testing.T) {
+	typ := reflect.TypeOf(schemaTestAnimal{})
+
+	raw := json.RawMessage(`{"id":"1","name":"dog"}`)
+	if err := checkExtraFields(typ, raw); err != nil {
+		t.Fatalf("expected promoted embedded field to validate, got %v", err)
+	}
+
+	raw = json.RawMessage(`{"schemaTestBase":{"id":"1"},"name":"dog"}`)
+	if err := checkExtraFields(typ, raw); err == nil {
+		t.Fatal("expected a nested key matching the embedded type's name to be rejected")
+	}
+}
+
+func TestCheckExtraFields(t *testing.T) {
+	typ := reflect.TypeOf(schemaTestPerson{})
+
+	t.Run("no extra fields", func(t *testing.T) {
+		raw := json.RawMessage(`{"name":"alice","addresses":[{"city":"nyc"}]}`)
+		if err := checkExtraFields(typ, raw); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("top-level extra field", func(t *testing.T) {
+		raw := json.RawMessage(`{"name":"alice","extra":"leaked"}`)
+		if err := checkExtraFields(typ, raw); err == nil {
+			t.Fatal("expected an error for the unexpected top-level field")
+		}
+	})
+
+	t.Run("nested extra field", func(t *testing.T) {
+		raw := json.RawMessage(`{"name":"alice","addresses":[{"city":"nyc","country":"us"}]}`)
+		if err := checkExtraFields(typ, raw); err == nil {
+			t.Fatal("expected an error for the unexpected nested field")
+		}
+	})
+}
+
+func TestCheckExtraFieldsRecursesIntoMapValues(t *testing.T) {
+	typ := reflect.TypeOf(schemaTestDirectory{})
+
+	raw := json.RawMessage(`{"entries":{"home":{"city":"nyc"}}}`)
+	if err := checkExtraFields(typ, raw); err != nil {
+		t.Fatalf("expected no error for a valid map value, got %v", err)
+	}
+
+	raw = json.RawMessage(`{"entries":{"home":{"city":"nyc","country":"us"}}}`)
+	if err := checkExtraFields(typ, raw); err == nil {
+		t.Fatal("expected an error for an unexpected field inside a map value")
+	}
+}
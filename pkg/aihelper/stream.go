@@ -0,0 +1,205 @@
+package aihelper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/gptscript-ai/go-gptscript"
+)
+
+// StreamEventType identifies the kind of incremental update carried by a
+// StreamEvent.
+type StreamEventType string
+
+const (
+	// StreamEventDelta carries an incremental chunk of model output.
+	StreamEventDelta StreamEventType = "delta"
+	// StreamEventDone indicates the run has finished. Content holds the
+	// full, final text for the run, and Err is set if the run (or, for
+	// GenerateObjectStream, the subsequent unmarshal) failed.
+	StreamEventDone StreamEventType = "done"
+)
+
+// StreamEvent is a single incremental update emitted while a prompt runs.
+// Consumers should keep reading from the channel until a StreamEventDone
+// event is received; the channel is closed immediately after.
+type StreamEvent struct {
+	Type    StreamEventType
+	Content string
+	Err     error
+}
+
+// GenerateStringStream behaves like GenerateObject with a *string output,
+// except that it returns a channel of StreamEvent as tokens arrive instead
+// of blocking until the run completes. Like GenerateObject, it honors any
+// rate limit configured via WithRateLimit, retries a rate-limited call with
+// backoff, records token usage, and serves/populates the cache - caching
+// and usage accounting happen once the full response is known, since both
+// depend on the final text rather than individual deltas.
+func (a *AIHelper) GenerateStringStream(ctx context.Context, instructions, input string, opts ...GenerateOption) (<-chan StreamEvent, error) {
+	o := newGenerateOptions(a, opts...)
+
+	var key string
+	if a.cache != nil && !o.skipCache {
+		key = cacheKey(o, instructions, input, nil)
+		if cached, ok, err := a.cache.Get(ctx, key); err == nil && ok {
+			a.recordCacheHit()
+			return cachedEventStream(cached), nil
+		}
+		a.recordCacheMiss()
+	}
+
+	promptTokens := countTokens(o.modelName, instructions+input)
+	run, err := a.evaluateStreamRun(ctx, o, o.toolDef(instructions, false, nil), input, promptTokens)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.streamEvents(ctx, run, nil, o, promptTokens, key), nil
+}
+
+// GenerateObjectStream behaves like GenerateObject for non-string output,
+// except that it returns a channel of StreamEvent as tokens arrive instead
+// of blocking until the run completes. Unlike GenerateObject, it does not
+// accept a *string output - GenerateObject's raw-text passthrough for
+// *string has no streaming equivalent here, since forcing JSON mode and
+// unmarshaling into a string would silently require a quoted JSON string
+// rather than raw text; use GenerateStringStream instead. Since output is
+// only valid once the full response has been received, tokens are buffered
+// internally and only unmarshaled into output once the run is done;
+// StreamEventDelta events are emitted for progress (e.g. to drive a
+// "typing" indicator) and carry the raw, not-yet-valid JSON seen so far.
+// Like GenerateObject, it honors any rate limit configured via
+// WithRateLimit, retries a rate-limited call with backoff, records token
+// usage, and serves/populates the cache once the final text is known; it
+// does not, however, retry a malformed response the way GenerateObject
+// does - a failed unmarshal is reported as an Err on the closing
+// StreamEventDone instead.
+func (a *AIHelper) GenerateObjectStream(ctx context.Context, output any, instructions, input string, opts ...GenerateOption) (<-chan StreamEvent, error) {
+	if _, isString := output.(*string); isString {
+		return nil, fmt.Errorf("aihelper: GenerateObjectStream does not support *string output; use GenerateStringStream instead")
+	}
+
+	o := newGenerateOptions(a, opts...)
+	schema := generateSchema(reflect.TypeOf(output), o.strictSchema)
+
+	var key string
+	if a.cache != nil && !o.skipCache {
+		key = cacheKey(o, instructions, input, schema)
+		if cached, ok, err := a.cache.Get(ctx, key); err == nil && ok {
+			a.recordCacheHit()
+			if err := json.Unmarshal([]byte(cached), output); err != nil {
+				return nil, err
+			}
+			return cachedEventStream(cached), nil
+		}
+		a.recordCacheMiss()
+	}
+
+	promptTokens := countTokens(o.modelName, instructions+input)
+	run, err := a.evaluateStreamRun(ctx, o, o.toolDef(instructions, true, schema), input, promptTokens)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.streamEvents(ctx, run, output, o, promptTokens, key), nil
+}
+
+// evaluateStreamRun starts toolDef running against input, honoring any rate
+// limit configured for o.modelName and retrying with backoff if the
+// provider reports a rate limit error before the run even starts.
+func (a *AIHelper) evaluateStreamRun(ctx context.Context, o generateOptions, toolDef gptscript.ToolDef, input string, promptTokens int) (streamRun, error) {
+	return retryOnRateLimit(ctx, func() (streamRun, error) {
+		if err := a.wait(ctx, o.modelName, promptTokens); err != nil {
+			return nil, err
+		}
+		return a.gptscript.Evaluate(ctx, gptscript.Options{
+			Input: input,
+		}, toolDef)
+	})
+}
+
+// cachedEventStream returns a single already-closed StreamEventDone event
+// carrying content, for a cache hit where there are no real deltas to
+// stream.
+func cachedEventStream(content string) <-chan StreamEvent {
+	events := make(chan StreamEvent, 1)
+	events <- StreamEvent{Type: StreamEventDone, Content: content}
+	close(events)
+	return events
+}
+
+// streamRun is the subset of *gptscript.Run that streamEvents depends on,
+// narrowed to an interface so streamEvents can be exercised with a fake run
+// in tests instead of a live gptscript call.
+type streamRun interface {
+	Events() <-chan gptscript.Frame
+	Text() (string, error)
+}
+
+// streamEvents drains run.Events(), forwarding each token as a
+// StreamEventDelta. Once the run is done, it records token usage under
+// o.usageLabel, unmarshals the final text into output if non-nil
+// (rejecting undeclared fields the same way GenerateObject does when
+// o.strictSchema is set), and populates the cache under key (if non-empty)
+// before sending the closing StreamEventDone event.
+func (a *AIHelper) streamEvents(ctx context.Context, run streamRun, output any, o generateOptions, promptTokens int, key string) <-chan StreamEvent {
+	events := make(chan StreamEvent)
+
+	go func() {
+		defer close(events)
+
+		for frame := range run.Events() {
+			if frame.Call == nil || frame.Call.Content == "" {
+				continue
+			}
+
+			select {
+			case events <- StreamEvent{Type: StreamEventDelta, Content: frame.Call.Content}:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		text, err := run.Text()
+		if err != nil {
+			sendEvent(ctx, events, StreamEvent{Type: StreamEventDone, Err: err})
+			return
+		}
+
+		a.usage.record(o.usageLabel, o.modelName, promptTokens, countTokens(o.modelName, text))
+
+		if output != nil {
+			if err := json.Unmarshal([]byte(text), output); err != nil {
+				sendEvent(ctx, events, StreamEvent{Type: StreamEventDone, Content: text, Err: err})
+				return
+			}
+			if o.strictSchema {
+				if err := checkExtraFields(reflect.TypeOf(output), json.RawMessage(text)); err != nil {
+					sendEvent(ctx, events, StreamEvent{Type: StreamEventDone, Content: text, Err: err})
+					return
+				}
+			}
+		}
+
+		if key != "" {
+			_ = a.cache.Set(ctx, key, text)
+		}
+
+		sendEvent(ctx, events, StreamEvent{Type: StreamEventDone, Content: text})
+	}()
+
+	return events
+}
+
+// sendEvent sends ev on events, or gives up if ctx is done first - e.g.
+// because the caller already stopped reading after tearing down on
+// ctx.Done() itself, which would otherwise block this goroutine forever.
+func sendEvent(ctx context.Context, events chan<- StreamEvent, ev StreamEvent) {
+	select {
+	case events <- ev:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,116 @@
+package aihelper
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/gptscript-ai/go-gptscript"
+)
+
+type fakeStreamRun struct {
+	frames chan gptscript.Frame
+	text   string
+	err    error
+}
+
+func (f *fakeStreamRun) Events() <-chan gptscript.Frame { return f.frames }
+func (f *fakeStreamRun) Text() (string, error)          { return f.text, f.err }
+
+type streamTestPerson struct {
+	Name string `json:"name"`
+}
+
+func TestStreamEventsTerminalSendDoesNotLeakOnCtxCancellation(t *testing.T) {
+	a := &AIHelper{usage: newUsageTracker()}
+	o := newGenerateOptions(a)
+
+	frames := make(chan gptscript.Frame)
+	close(frames)
+	run := &fakeStreamRun{frames: frames, text: "hello"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	events := a.streamEvents(ctx, run, nil, o, 0, "")
+
+	// Poll with a non-blocking receive instead of sleeping then reading
+	// once: a receive that only becomes "ready" via the default case
+	// never parks a goroutine on events' wait queue, so it can never be
+	// the rendezvous target sendEvent's select picks between - unlike a
+	// blocking receive, which would itself race sendEvent's ctx.Done()
+	// case and could flakily "win" if scheduled first.
+	deadline := time.After(time.Second)
+	for {
+		select {
+		case ev, ok := <-events:
+			if ok {
+				t.Fatalf("expected the channel to be closed without a terminal event once ctx was cancelled before any consumer read, got %+v", ev)
+			}
+			return
+		case <-deadline:
+			t.Fatal("streamEvents goroutine leaked: terminal send blocked past ctx cancellation with no consumer")
+		default:
+			runtime.Gosched()
+		}
+	}
+}
+
+func TestGenerateObjectStreamRejectsStringOutput(t *testing.T) {
+	a := &AIHelper{usage: newUsageTracker()}
+
+	var output string
+	events, err := a.GenerateObjectStream(context.Background(), &output, "summarize", "some input")
+
+	if events != nil {
+		t.Fatalf("expected no event channel for a rejected call, got %+v", events)
+	}
+	if err == nil {
+		t.Fatal("expected GenerateObjectStream to reject *string output")
+	}
+}
+
+func TestStreamEventsRejectsExtraFieldsWhenStrict(t *testing.T) {
+	a := &AIHelper{usage: newUsageTracker()}
+	o := newGenerateOptions(a, StrictSchema())
+
+	frames := make(chan gptscript.Frame)
+	close(frames)
+	run := &fakeStreamRun{frames: frames, text: `{"name":"alice","extra":"leaked"}`}
+
+	var output streamTestPerson
+	events := a.streamEvents(context.Background(), run, &output, o, 0, "")
+
+	var done StreamEvent
+	for ev := range events {
+		done = ev
+	}
+
+	if done.Type != StreamEventDone || done.Err == nil {
+		t.Fatalf("expected a StreamEventDone with an error for the unexpected field, got %+v", done)
+	}
+}
+
+func TestStreamEventsAllowsExtraFieldsWhenNotStrict(t *testing.T) {
+	a := &AIHelper{usage: newUsageTracker()}
+	o := newGenerateOptions(a)
+
+	frames := make(chan gptscript.Frame)
+	close(frames)
+	run := &fakeStreamRun{frames: frames, text: `{"name":"alice","extra":"leaked"}`}
+
+	var output streamTestPerson
+	events := a.streamEvents(context.Background(), run, &output, o, 0, "")
+
+	var done StreamEvent
+	for ev := range events {
+		done = ev
+	}
+
+	if done.Type != StreamEventDone || done.Err != nil {
+		t.Fatalf("expected a successful StreamEventDone without StrictSchema, got %+v", done)
+	}
+	if output.Name != "alice" {
+		t.Fatalf("expected output to be populated, got %+v", output)
+	}
+}
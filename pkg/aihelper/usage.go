@@ -0,0 +1,103 @@
+package aihelper
+
+import (
+	"sync"
+
+	"github.com/pkoukk/tiktoken-go"
+)
+
+// Usage is a token count and cost snapshot for one or more calls, as
+// returned by AIHelper.Usage.
+type Usage struct {
+	PromptTokens     int64
+	CompletionTokens int64
+	TotalTokens      int64
+	EstimatedCostUSD float64
+}
+
+// modelPricing is USD per 1,000 tokens. Models not listed here still have
+// their tokens counted, just not costed.
+type modelPricing struct {
+	promptPer1K     float64
+	completionPer1K float64
+}
+
+var defaultModelPricing = map[string]modelPricing{
+	"gpt-4o":        {promptPer1K: 0.0025, completionPer1K: 0.01},
+	"gpt-4o-mini":   {promptPer1K: 0.00015, completionPer1K: 0.0006},
+	"gpt-3.5-turbo": {promptPer1K: 0.0005, completionPer1K: 0.0015},
+}
+
+// usageTracker aggregates Usage by caller-supplied label.
+type usageTracker struct {
+	mu      sync.Mutex
+	byLabel map[string]*Usage
+}
+
+func newUsageTracker() *usageTracker {
+	return &usageTracker{byLabel: map[string]*Usage{}}
+}
+
+func (t *usageTracker) record(label, model string, promptTokens, completionTokens int) {
+	cost := estimateCost(model, promptTokens, completionTokens)
+
+	t.mu.Lock()
+	u, ok := t.byLabel[label]
+	if !ok {
+		u = &Usage{}
+		t.byLabel[label] = u
+	}
+	u.PromptTokens += int64(promptTokens)
+	u.CompletionTokens += int64(completionTokens)
+	u.TotalTokens += int64(promptTokens + completionTokens)
+	u.EstimatedCostUSD += cost
+	t.mu.Unlock()
+
+	tokensTotal.WithLabelValues(label, model, "prompt").Add(float64(promptTokens))
+	tokensTotal.WithLabelValues(label, model, "completion").Add(float64(completionTokens))
+	estimatedCostUSDTotal.WithLabelValues(label, model).Add(cost)
+}
+
+func (t *usageTracker) snapshot(label string) Usage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if u, ok := t.byLabel[label]; ok {
+		return *u
+	}
+	return Usage{}
+}
+
+func estimateCost(model string, promptTokens, completionTokens int) float64 {
+	pricing, ok := defaultModelPricing[model]
+	if !ok {
+		return 0
+	}
+	return float64(promptTokens)/1000*pricing.promptPer1K + float64(completionTokens)/1000*pricing.completionPer1K
+}
+
+// Usage returns a snapshot of token usage and estimated cost recorded under
+// label via WithUsageLabel (the zero value aggregates unlabeled calls).
+func (a *AIHelper) Usage(label string) Usage {
+	return a.usage.snapshot(label)
+}
+
+// countTokens counts the tokens gptscript's response didn't come with a
+// count for, using model's own tokenizer where known and falling back to
+// the cl100k_base encoding (used by the GPT-3.5/4 family) otherwise.
+func countTokens(model, text string) int {
+	enc, err := tiktoken.EncodingForModel(model)
+	if err != nil {
+		enc, err = tiktoken.GetEncoding("cl100k_base")
+	}
+	if err != nil {
+		return estimateTokensByLength(text)
+	}
+	return len(enc.Encode(text, nil, nil))
+}
+
+// estimateTokensByLength is the last-resort fallback when no tiktoken
+// encoding is available at all: roughly 4 characters per token.
+func estimateTokensByLength(text string) int {
+	return (len(text) + 3) / 4
+}
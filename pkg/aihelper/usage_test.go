@@ -0,0 +1,63 @@
+package aihelper
+
+import "testing"
+
+func TestEstimateCostKnownModel(t *testing.T) {
+	got := estimateCost("gpt-4o", 1000, 1000)
+	want := defaultModelPricing["gpt-4o"].promptPer1K + defaultModelPricing["gpt-4o"].completionPer1K
+	if got != want {
+		t.Fatalf("estimateCost(gpt-4o, 1000, 1000) = %v, want %v", got, want)
+	}
+}
+
+func TestEstimateCostUnknownModel(t *testing.T) {
+	if got := estimateCost("some-unpriced-model", 1000, 1000); got != 0 {
+		t.Fatalf("expected 0 cost for an unpriced model, got %v", got)
+	}
+}
+
+func TestUsageTrackerRecordAggregatesByLabel(t *testing.T) {
+	tr := newUsageTracker()
+
+	tr.record("alice", "gpt-4o", 100, 50)
+	tr.record("alice", "gpt-4o", 10, 5)
+	tr.record("bob", "gpt-4o", 1, 1)
+
+	alice := tr.snapshot("alice")
+	if alice.PromptTokens != 110 || alice.CompletionTokens != 55 || alice.TotalTokens != 165 {
+		t.Fatalf("unexpected aggregation for \"alice\": %+v", alice)
+	}
+	if alice.EstimatedCostUSD <= 0 {
+		t.Fatalf("expected a positive estimated cost for a priced model, got %v", alice.EstimatedCostUSD)
+	}
+
+	bob := tr.snapshot("bob")
+	if bob.PromptTokens != 1 || bob.CompletionTokens != 1 {
+		t.Fatalf("expected \"bob\"'s usage to stay separate from \"alice\"'s, got %+v", bob)
+	}
+}
+
+func TestUsageTrackerSnapshotUnknownLabel(t *testing.T) {
+	tr := newUsageTracker()
+	if got := tr.snapshot("never-recorded"); got != (Usage{}) {
+		t.Fatalf("expected a zero-value Usage for an unrecorded label, got %+v", got)
+	}
+}
+
+func TestEstimateTokensByLength(t *testing.T) {
+	cases := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"abcd", 1},
+		{"abcdefgh", 2},
+		{"abcdefghi", 3},
+	}
+
+	for _, c := range cases {
+		if got := estimateTokensByLength(c.text); got != c.want {
+			t.Fatalf("estimateTokensByLength(%q) = %d, want %d", c.text, got, c.want)
+		}
+	}
+}